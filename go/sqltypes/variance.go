@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqltypes
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ToFloat64 converts a numeric Value into a float64, promoting ints,
+// unsigned ints, decimals and floats alike. It exists for aggregates such as
+// VAR_POP/VAR_SAMP/STDDEV_POP/STDDEV_SAMP that combine partial sums of
+// differently-typed numeric columns coming back from different shards.
+func (v Value) ToFloat64() (float64, error) {
+	if v.IsNull() {
+		return 0, nil
+	}
+	if !v.IsNumeric() {
+		return 0, fmt.Errorf("%v is not numeric", v)
+	}
+	f, err := strconv.ParseFloat(v.ToString(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a valid number: %v", v, err)
+	}
+	return f, nil
+}
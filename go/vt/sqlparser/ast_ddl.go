@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlparser
+
+// DDLAction distinguishes the kind of DDL statement a *DDL node holds.
+type DDLAction string
+
+// DDL actions.
+const (
+	CreateStr     DDLAction = "create"
+	AlterStr      DDLAction = "alter"
+	DropStr       DDLAction = "drop"
+	RenameStr     DDLAction = "rename"
+	TruncateStr   DDLAction = "truncate"
+	CreateViewStr DDLAction = "create view"
+	AlterViewStr  DDLAction = "alter view"
+	DropViewStr   DDLAction = "drop view"
+)
+
+// DDL represents a CREATE, ALTER, DROP, RENAME or TRUNCATE statement.
+// View is only populated for CreateViewStr, carrying the
+// attributes (algorithm/definer/security/check option) that are specific
+// to views and don't apply to any other DDL action.
+type DDL struct {
+	Action DDLAction
+	Table  TableName
+	View   *ViewSpec
+}
+
+// Format formats the node.
+func (node *DDL) Format(buf *TrackedBuffer) {
+	if node.View != nil {
+		node.View.Format(buf)
+		return
+	}
+	buf.Myprintf("%s table %v", string(node.Action), node.Table)
+}
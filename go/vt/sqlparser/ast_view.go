@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlparser
+
+// ViewAlgorithm is the ALGORITHM clause of a CREATE VIEW statement.
+type ViewAlgorithm int8
+
+// Algorithm values, as defined by MySQL's CREATE VIEW syntax.
+const (
+	ViewAlgorithmUndefined ViewAlgorithm = iota
+	ViewAlgorithmMerge
+	ViewAlgorithmTempTable
+)
+
+// Format formats the node.
+func (a ViewAlgorithm) Format(buf *TrackedBuffer) {
+	switch a {
+	case ViewAlgorithmMerge:
+		buf.Myprintf("merge")
+	case ViewAlgorithmTempTable:
+		buf.Myprintf("temptable")
+	default:
+		buf.Myprintf("undefined")
+	}
+}
+
+// ViewSecurity is the SQL SECURITY context a view's body executes under.
+type ViewSecurity int8
+
+// Security values, as defined by MySQL's CREATE VIEW syntax.
+const (
+	ViewSecurityDefiner ViewSecurity = iota
+	ViewSecurityInvoker
+)
+
+// Format formats the node.
+func (s ViewSecurity) Format(buf *TrackedBuffer) {
+	if s == ViewSecurityInvoker {
+		buf.Myprintf("invoker")
+		return
+	}
+	buf.Myprintf("definer")
+}
+
+// ViewCheckOption is the WITH [LOCAL|CASCADED] CHECK OPTION clause of a
+// CREATE VIEW statement.
+type ViewCheckOption int8
+
+// Check option values, as defined by MySQL's CREATE VIEW syntax.
+const (
+	ViewCheckOptionNone ViewCheckOption = iota
+	ViewCheckOptionLocal
+	ViewCheckOptionCascaded
+)
+
+// Format formats the node.
+func (c ViewCheckOption) Format(buf *TrackedBuffer) {
+	switch c {
+	case ViewCheckOptionLocal:
+		buf.Myprintf(" with local check option")
+	case ViewCheckOptionCascaded:
+		buf.Myprintf(" with cascaded check option")
+	}
+}
+
+// ViewSpec carries the MySQL-specific attributes of a CREATE VIEW statement
+// that aren't shared with any other DDL: the ALGORITHM, DEFINER, SQL
+// SECURITY and WITH CHECK OPTION clauses, together with the view's name and
+// its underlying SELECT. DDL.View holds the ViewSpec for a CreateViewStr DDL
+// so that the rest of the DDL struct doesn't have to grow view-only fields.
+type ViewSpec struct {
+	ViewName    TableName
+	Algorithm   ViewAlgorithm
+	Definer     string
+	Security    ViewSecurity
+	Columns     Columns
+	Select      SelectStatement
+	CheckOption ViewCheckOption
+}
+
+// Format formats the node so that a CREATE VIEW round-trips with the same
+// algorithm, definer, security and check option it was parsed with. This
+// matters because the statement is re-sent verbatim to every shard of the
+// view's keyspace.
+func (v *ViewSpec) Format(buf *TrackedBuffer) {
+	buf.Myprintf("create algorithm = %v ", v.Algorithm)
+	if v.Definer != "" {
+		buf.Myprintf("definer = %s ", v.Definer)
+	}
+	buf.Myprintf("sql security %v view %v", v.Security, v.ViewName)
+	if len(v.Columns) > 0 {
+		buf.Myprintf("%v", v.Columns)
+	}
+	buf.Myprintf(" as %v", v.Select)
+	v.CheckOption.Format(buf)
+}
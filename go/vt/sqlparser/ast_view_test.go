@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlparser
+
+import "testing"
+
+func TestViewSpecFormat(t *testing.T) {
+	spec := &ViewSpec{
+		ViewName:    TableName{Name: NewTableIdent("v1")},
+		Algorithm:   ViewAlgorithmMerge,
+		Definer:     "'root'@'localhost'",
+		Security:    ViewSecurityInvoker,
+		CheckOption: ViewCheckOptionCascaded,
+		Select: &Select{
+			SelectExprs: SelectExprs{&StarExpr{}},
+			From:        TableExprs{&AliasedTableExpr{Expr: TableName{Name: NewTableIdent("t")}}},
+		},
+	}
+
+	buf := NewTrackedBuffer(nil)
+	spec.Format(buf)
+	want := "create algorithm = merge definer = 'root'@'localhost' sql security invoker view v1 as select * from t with cascaded check option"
+	if got := buf.String(); got != want {
+		t.Errorf("ViewSpec.Format() = %q, want %q", got, want)
+	}
+}
+
+func TestViewAlgorithmFormat(t *testing.T) {
+	cases := []struct {
+		in   ViewAlgorithm
+		want string
+	}{
+		{ViewAlgorithmUndefined, "undefined"},
+		{ViewAlgorithmMerge, "merge"},
+		{ViewAlgorithmTempTable, "temptable"},
+	}
+	for _, c := range cases {
+		buf := NewTrackedBuffer(nil)
+		c.in.Format(buf)
+		if got := buf.String(); got != c.want {
+			t.Errorf("ViewAlgorithm(%d).Format() = %q, want %q", int8(c.in), got, c.want)
+		}
+	}
+}
+
+func TestViewCheckOptionFormat(t *testing.T) {
+	cases := []struct {
+		in   ViewCheckOption
+		want string
+	}{
+		{ViewCheckOptionNone, ""},
+		{ViewCheckOptionLocal, " with local check option"},
+		{ViewCheckOptionCascaded, " with cascaded check option"},
+	}
+	for _, c := range cases {
+		buf := NewTrackedBuffer(nil)
+		c.in.Format(buf)
+		if got := buf.String(); got != c.want {
+			t.Errorf("ViewCheckOption(%d).Format() = %q, want %q", int8(c.in), got, c.want)
+		}
+	}
+}
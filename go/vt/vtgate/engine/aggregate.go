@@ -0,0 +1,316 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// AggregateOpcode is the aggregation function of a single column that
+// OrderedAggregate combines across the rows of a group.
+type AggregateOpcode int
+
+// The complete set of aggregate opcodes OrderedAggregate knows how to merge.
+// var_pop/var_samp/stddev_pop/stddev_samp extend this same block rather than
+// using a separate numeric range, so merge() and finalize() can switch on
+// one contiguous enum instead of stitching two together.
+const (
+	AggregateCount AggregateOpcode = iota
+	AggregateSum
+	AggregateMin
+	AggregateMax
+	AggregateVarPop
+	AggregateVarSamp
+	AggregateStddevPop
+	AggregateStddevSamp
+)
+
+// aggregateName gives each opcode a name for plan descriptions and errors.
+var aggregateName = map[AggregateOpcode]string{
+	AggregateCount:      "count",
+	AggregateSum:        "sum",
+	AggregateMin:        "min",
+	AggregateMax:        "max",
+	AggregateVarPop:     "var_pop",
+	AggregateVarSamp:    "var_samp",
+	AggregateStddevPop:  "stddev_pop",
+	AggregateStddevSamp: "stddev_samp",
+}
+
+// String returns the opcode's SQL function name.
+func (code AggregateOpcode) String() string {
+	name, ok := aggregateName[code]
+	if !ok {
+		return fmt.Sprintf("AggregateOpcode(%d)", code)
+	}
+	return name
+}
+
+// isVarianceOpcode reports whether code is one of the var_pop/var_samp/
+// stddev_pop/stddev_samp family, which -- unlike count/sum/min/max -- isn't
+// merged column-by-column: it's reconstructed from three partial columns
+// (sum(x), sum(x*x), count(x)) that the planbuilder laid out consecutively.
+func isVarianceOpcode(code AggregateOpcode) bool {
+	switch code {
+	case AggregateVarPop, AggregateVarSamp, AggregateStddevPop, AggregateStddevSamp:
+		return true
+	}
+	return false
+}
+
+// AggregateParams describes one aggregate column OrderedAggregate must
+// merge. Col is the column's index in the row scattered to (and merged
+// from) Input: for a variance/stddev opcode, that's the index of the first
+// of its three rewritten partial columns (sum(x), sum(x*x), count(x)).
+// Alias names the synthesized output field for a variance/stddev opcode,
+// whose scattered field names (sum(x), sum(x*x), count(x)) aren't what the
+// caller asked for.
+type AggregateParams struct {
+	Opcode AggregateOpcode
+	Col    int
+	Alias  string
+}
+
+// ResultColumn describes one column of OrderedAggregate's final, logical
+// result, in the original select-list order: either the Index'th entry of
+// Aggregates, or the Index'th entry of OrderedAggregate.PassthroughCols.
+type ResultColumn struct {
+	IsAggregate bool
+	Index       int
+}
+
+// OrderedAggregate is a primitive that expects its input to be ordered by
+// the GROUP BY keys, merges the rows of each group into a single row, and
+// projects that row down to one output column per original select
+// expression -- collapsing a variance/stddev aggregate's three scattered
+// partial columns back into the single column the caller selected.
+type OrderedAggregate struct {
+	// Aggregates describes each aggregate column to merge, including the
+	// var_pop/var_samp/stddev_pop/stddev_samp family alongside count/sum/
+	// min/max.
+	Aggregates []AggregateParams
+
+	// Keys are the indices, in the scattered row, of the columns the input
+	// is ordered (and grouped) by.
+	Keys []int
+
+	// PassthroughCols are the indices, in the scattered row, of the
+	// non-aggregate select columns, in the order they're referenced by
+	// ResultColumns.
+	PassthroughCols []int
+
+	// ResultColumns maps each column of the final, logical result (one per
+	// original select expression) back to either Aggregates or
+	// PassthroughCols.
+	ResultColumns []ResultColumn
+
+	Input Primitive
+}
+
+// RouteType returns a description of the plan.
+func (oa *OrderedAggregate) RouteType() string {
+	return "OrderedAggregate"
+}
+
+// GetKeyspaceName specifies the keyspace that this primitive routes to.
+func (oa *OrderedAggregate) GetKeyspaceName() string {
+	return oa.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (oa *OrderedAggregate) GetTableName() string {
+	return oa.Input.GetTableName()
+}
+
+// Execute merges consecutive rows of its (already GROUP-BY-ordered) input
+// that share the same group-by key, reconstructs each aggregate column's
+// final value for every group, and projects the result down to the
+// original, logical column shape described by ResultColumns.
+func (oa *OrderedAggregate) Execute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	input, err := oa.Input.Execute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqltypes.Result{Fields: oa.projectFields(input.Fields)}
+	var current []sqltypes.Value
+	for _, row := range input.Rows {
+		if current != nil && oa.sameGroup(current, row) {
+			if current, err = oa.merge(current, row); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if current != nil {
+			out.Rows = append(out.Rows, oa.project(oa.finalize(current)))
+		}
+		current = row
+	}
+	if current != nil {
+		out.Rows = append(out.Rows, oa.project(oa.finalize(current)))
+	}
+	out.RowsAffected = uint64(len(out.Rows))
+	return out, nil
+}
+
+// StreamExecute doesn't stream for OrderedAggregate: a group can only be
+// finalized once every row for it has been seen, so it buffers like Execute
+// and passes the whole result to callback in one call.
+func (oa *OrderedAggregate) StreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	result, err := oa.Execute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(result)
+}
+
+// GetFields fetches the field info from the underlying input and projects
+// it down to the same logical column shape Execute produces.
+func (oa *OrderedAggregate) GetFields(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	result, err := oa.Input.GetFields(vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: oa.projectFields(result.Fields)}, nil
+}
+
+// projectFields builds the final, logical field list from the scattered
+// row's fields: a passthrough column's field is taken as-is, while an
+// aggregate column's field is either the scattered field itself (count/sum/
+// min/max, which are already named and typed correctly) or a synthesized
+// one (the variance/stddev family, whose scattered fields are sum(x)/
+// sum(x*x)/count(x), not what the caller asked for).
+func (oa *OrderedAggregate) projectFields(scattered []*querypb.Field) []*querypb.Field {
+	if scattered == nil {
+		return nil
+	}
+	fields := make([]*querypb.Field, len(oa.ResultColumns))
+	for i, rc := range oa.ResultColumns {
+		if !rc.IsAggregate {
+			fields[i] = scattered[oa.PassthroughCols[rc.Index]]
+			continue
+		}
+		aggr := oa.Aggregates[rc.Index]
+		if isVarianceOpcode(aggr.Opcode) {
+			fields[i] = &querypb.Field{Name: aggr.Alias, Type: sqltypes.Float64}
+			continue
+		}
+		fields[i] = scattered[aggr.Col]
+	}
+	return fields
+}
+
+// project collapses a finalized, scattered-width row down to the final,
+// logical row: one value per ResultColumns entry.
+func (oa *OrderedAggregate) project(row []sqltypes.Value) []sqltypes.Value {
+	result := make([]sqltypes.Value, len(oa.ResultColumns))
+	for i, rc := range oa.ResultColumns {
+		if rc.IsAggregate {
+			result[i] = row[oa.Aggregates[rc.Index].Col]
+		} else {
+			result[i] = row[oa.PassthroughCols[rc.Index]]
+		}
+	}
+	return result
+}
+
+func (oa *OrderedAggregate) sameGroup(current, row []sqltypes.Value) bool {
+	for _, key := range oa.Keys {
+		if !current[key].Equal(row[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// merge folds row into current for every aggregate column. count/sum are
+// added in place and min/max are compared in place; the variance/stddev
+// family instead accumulates its three partials (sum(x), sum(x*x), count(x))
+// so they can be collapsed to a single value once the group is complete.
+func (oa *OrderedAggregate) merge(current, row []sqltypes.Value) ([]sqltypes.Value, error) {
+	for _, aggr := range oa.Aggregates {
+		switch aggr.Opcode {
+		case AggregateCount, AggregateSum:
+			sum, err := current[aggr.Col].ToFloat64()
+			if err != nil {
+				return nil, err
+			}
+			v, err := row[aggr.Col].ToFloat64()
+			if err != nil {
+				return nil, err
+			}
+			current[aggr.Col] = float64Value(sum + v)
+		case AggregateMin:
+			if row[aggr.Col].Less(current[aggr.Col]) {
+				current[aggr.Col] = row[aggr.Col]
+			}
+		case AggregateMax:
+			if current[aggr.Col].Less(row[aggr.Col]) {
+				current[aggr.Col] = row[aggr.Col]
+			}
+		default:
+			if !isVarianceOpcode(aggr.Opcode) {
+				return nil, fmt.Errorf("BUG: unexpected opcode in OrderedAggregate: %v", aggr.Opcode)
+			}
+			if err := oa.mergeVarianceColumn(current, row, aggr.Col); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return current, nil
+}
+
+func (oa *OrderedAggregate) mergeVarianceColumn(current, row []sqltypes.Value, col int) error {
+	sumX, sumX2, n, err := mergeVariancePartial(0, 0, 0, current[col:col+varianceNumPartials])
+	if err != nil {
+		return err
+	}
+	sumX, sumX2, n, err = mergeVariancePartial(sumX, sumX2, n, row[col:col+varianceNumPartials])
+	if err != nil {
+		return err
+	}
+	current[col] = float64Value(sumX)
+	current[col+1] = float64Value(sumX2)
+	current[col+2] = int64Value(n)
+	return nil
+}
+
+// finalize collapses a merged group row's running totals into the values
+// the caller asked for: every variance/stddev column's three partials are
+// reduced to the single reconstructed result, left in the first of the
+// three columns.
+func (oa *OrderedAggregate) finalize(row []sqltypes.Value) []sqltypes.Value {
+	for _, aggr := range oa.Aggregates {
+		if !isVarianceOpcode(aggr.Opcode) {
+			continue
+		}
+		sumX, _ := row[aggr.Col].ToFloat64()
+		sumX2, _ := row[aggr.Col+1].ToFloat64()
+		n, _ := strconv.ParseInt(row[aggr.Col+2].ToString(), 10, 64)
+		row[aggr.Col] = varianceResult(aggr.Opcode, sumX, sumX2, n)
+	}
+	return row
+}
+
+func int64Value(n int64) sqltypes.Value {
+	return sqltypes.MakeTrusted(sqltypes.Int64, []byte(strconv.FormatInt(n, 10)))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// fakeAggregateInput is a minimal Primitive standing in for the route that
+// would normally scatter the rewritten query to shards and merge-sort the
+// results by the GROUP BY keys.
+type fakeAggregateInput struct {
+	result *sqltypes.Result
+}
+
+func (f *fakeAggregateInput) RouteType() string       { return "fake" }
+func (f *fakeAggregateInput) GetKeyspaceName() string { return "ks" }
+func (f *fakeAggregateInput) GetTableName() string    { return "t" }
+
+func (f *fakeAggregateInput) Execute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	return f.result, nil
+}
+
+func (f *fakeAggregateInput) StreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	return callback(f.result)
+}
+
+func (f *fakeAggregateInput) GetFields(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{Fields: f.result.Fields}, nil
+}
+
+// TestOrderedAggregateVarPopProjection exercises
+// "SELECT id, VAR_POP(x), name FROM t GROUP BY id" end to end: two shards'
+// partials for the same group (x = 1..5, split 3/2 across shards) are
+// scattered as id, sum(x), sum(x*x), count(x), name, and must come back out
+// as exactly the three logical columns the caller selected.
+func TestOrderedAggregateVarPopProjection(t *testing.T) {
+	input := &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Name: "id", Type: sqltypes.Int64},
+			{Name: "sum(x)", Type: sqltypes.Int64},
+			{Name: "sum(x*x)", Type: sqltypes.Int64},
+			{Name: "count(x)", Type: sqltypes.Int64},
+			{Name: "name", Type: sqltypes.VarChar},
+		},
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt64(1), sqltypes.NewInt64(6), sqltypes.NewInt64(14), sqltypes.NewInt64(3), sqltypes.NewVarChar("a")},
+			{sqltypes.NewInt64(1), sqltypes.NewInt64(9), sqltypes.NewInt64(41), sqltypes.NewInt64(2), sqltypes.NewVarChar("a")},
+		},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates: []AggregateParams{
+			{Opcode: AggregateVarPop, Col: 1, Alias: "var_pop(x)"},
+		},
+		Keys:            []int{0},
+		PassthroughCols: []int{0, 4},
+		ResultColumns: []ResultColumn{
+			{IsAggregate: false, Index: 0}, // id
+			{IsAggregate: true, Index: 0},  // var_pop(x)
+			{IsAggregate: false, Index: 1}, // name
+		},
+		Input: &fakeAggregateInput{result: input},
+	}
+
+	result, err := oa.Execute(nil, nil, true)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	if len(result.Fields) != 3 {
+		t.Fatalf("len(Fields) = %d, want 3 (one per logical select expression)", len(result.Fields))
+	}
+	wantNames := []string{"id", "var_pop(x)", "name"}
+	for i, f := range result.Fields {
+		if f.Name != wantNames[i] {
+			t.Errorf("Fields[%d].Name = %q, want %q", i, f.Name, wantNames[i])
+		}
+	}
+	if result.Fields[1].Type != sqltypes.Float64 {
+		t.Errorf("Fields[1].Type = %v, want Float64", result.Fields[1].Type)
+	}
+
+	if len(result.Rows) != 1 {
+		t.Fatalf("len(Rows) = %d, want 1 group", len(result.Rows))
+	}
+	row := result.Rows[0]
+	if len(row) != 3 {
+		t.Fatalf("len(row) = %d, want 3", len(row))
+	}
+	if got := row[0].ToString(); got != "1" {
+		t.Errorf("row[0] (id) = %q, want 1", got)
+	}
+	if got := row[2].ToString(); got != "a" {
+		t.Errorf("row[2] (name) = %q, want a", got)
+	}
+	// x = 1..5: mean 3, population variance 2.
+	got, err := row[1].ToFloat64()
+	if err != nil {
+		t.Fatalf("row[1].ToFloat64() failed: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("row[1] (var_pop(x)) = %v, want 2", got)
+	}
+}
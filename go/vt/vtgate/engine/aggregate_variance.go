@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// MySQL can't compute VAR_POP/VAR_SAMP/STDDEV_POP/STDDEV_SAMP across shards
+// directly: each shard only sees its own rows. Instead, the planbuilder
+// rewrites the aggregate into three per-shard partials -- SUM(x), SUM(x*x)
+// and COUNT(x) -- and OrderedAggregate reconstructs the requested aggregate
+// here once the partials for a group have all arrived, using the standard
+// identities:
+//
+//	VAR_POP  = S2/n - (S1/n)^2
+//	VAR_SAMP = (S2 - S1^2/n) / (n-1)
+//
+// where S1 = sum(x), S2 = sum(x*x) and n = count(x). The STDDEV variants are
+// the square root of the corresponding variance.
+//
+// The AggregateVarPop/AggregateVarSamp/AggregateStddevPop/AggregateStddevSamp
+// opcodes themselves are declared in aggregate.go, as part of the same
+// AggregateOpcode block as count/sum/min/max, since OrderedAggregate needs a
+// single contiguous enum to switch on.
+
+// varianceNumPartials is the number of per-shard columns (sum(x), sum(x*x),
+// count(x)) a variance/stddev aggregate is rewritten into.
+const varianceNumPartials = 3
+
+// mergeVariancePartial folds one shard's (sum(x), sum(x*x), count(x)) row
+// into the running totals for a group. NULL inputs to the original
+// aggregate don't contribute to sum(x)/sum(x*x) and aren't counted, so the
+// partials already exclude them; there's nothing extra to special-case here.
+func mergeVariancePartial(sumX, sumX2 float64, n int64, partials []sqltypes.Value) (float64, float64, int64, error) {
+	if len(partials) != varianceNumPartials {
+		return 0, 0, 0, fmt.Errorf("BUG: expected %d variance partials, got %d", varianceNumPartials, len(partials))
+	}
+	s1, err := partials[0].ToFloat64()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	s2, err := partials[1].ToFloat64()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var count int64
+	if !partials[2].IsNull() {
+		count, err = strconv.ParseInt(partials[2].ToString(), 10, 64)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return sumX + s1, sumX2 + s2, n + count, nil
+}
+
+// varianceResult reconstructs the requested aggregate from totals accumulated
+// across every shard for a group. It returns SQL NULL when there isn't
+// enough data to define the result: no rows at all (n == 0), or exactly one
+// row for the sample variants (n == 1), which would otherwise divide by
+// zero.
+func varianceResult(opcode AggregateOpcode, sumX, sumX2 float64, n int64) sqltypes.Value {
+	if n == 0 {
+		return sqltypes.NULL
+	}
+	mean := sumX / float64(n)
+
+	switch opcode {
+	case AggregateVarPop:
+		return float64Value(sumX2/float64(n) - mean*mean)
+	case AggregateStddevPop:
+		return float64Value(math.Sqrt(math.Max(sumX2/float64(n)-mean*mean, 0)))
+	case AggregateVarSamp:
+		if n == 1 {
+			return sqltypes.NULL
+		}
+		return float64Value((sumX2 - sumX*mean) / float64(n-1))
+	case AggregateStddevSamp:
+		if n == 1 {
+			return sqltypes.NULL
+		}
+		return float64Value(math.Sqrt(math.Max((sumX2-sumX*mean)/float64(n-1), 0)))
+	default:
+		return sqltypes.NULL
+	}
+}
+
+func float64Value(f float64) sqltypes.Value {
+	return sqltypes.MakeTrusted(sqltypes.Float64, []byte(strconv.FormatFloat(f, 'g', -1, 64)))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestVarianceResult(t *testing.T) {
+	// 1, 2, 3, 4, 5: population variance 2, sample variance 2.5.
+	const sumX, sumX2, n = 15.0, 55.0, 5
+
+	cases := []struct {
+		name   string
+		opcode AggregateOpcode
+		sumX   float64
+		sumX2  float64
+		n      int64
+		want   float64
+		isNull bool
+	}{
+		{"var_pop", AggregateVarPop, sumX, sumX2, n, 2, false},
+		{"var_samp", AggregateVarSamp, sumX, sumX2, n, 2.5, false},
+		{"stddev_pop", AggregateStddevPop, sumX, sumX2, n, math.Sqrt(2), false},
+		{"stddev_samp", AggregateStddevSamp, sumX, sumX2, n, math.Sqrt(2.5), false},
+		{"var_pop no rows", AggregateVarPop, 0, 0, 0, 0, true},
+		{"var_samp no rows", AggregateVarSamp, 0, 0, 0, 0, true},
+		{"var_samp single row", AggregateVarSamp, 4, 16, 1, 0, true},
+		{"stddev_samp single row", AggregateStddevSamp, 4, 16, 1, 0, true},
+		// A single row has zero population variance, not a divide-by-zero.
+		{"var_pop single row", AggregateVarPop, 4, 16, 1, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := varianceResult(c.opcode, c.sumX, c.sumX2, c.n)
+			if c.isNull {
+				if !got.IsNull() {
+					t.Fatalf("varianceResult() = %v, want NULL", got)
+				}
+				return
+			}
+			f, err := got.ToFloat64()
+			if err != nil {
+				t.Fatalf("ToFloat64() failed: %v", err)
+			}
+			if math.Abs(f-c.want) > 1e-9 {
+				t.Errorf("varianceResult() = %v, want %v", f, c.want)
+			}
+		})
+	}
+}
+
+func TestMergeVariancePartial(t *testing.T) {
+	row := func(sum, sumSq, count int64) []sqltypes.Value {
+		return []sqltypes.Value{
+			sqltypes.NewInt64(sum),
+			sqltypes.NewInt64(sumSq),
+			sqltypes.NewInt64(count),
+		}
+	}
+
+	sumX, sumX2, n, err := mergeVariancePartial(0, 0, 0, row(1, 1, 1))
+	if err != nil {
+		t.Fatalf("mergeVariancePartial() failed: %v", err)
+	}
+	sumX, sumX2, n, err = mergeVariancePartial(sumX, sumX2, n, row(2, 4, 1))
+	if err != nil {
+		t.Fatalf("mergeVariancePartial() failed: %v", err)
+	}
+	if sumX != 3 || sumX2 != 5 || n != 2 {
+		t.Errorf("mergeVariancePartial() = (%v, %v, %v), want (3, 5, 2)", sumX, sumX2, n)
+	}
+
+	// A shard with no matching rows contributes a NULL count, not a row of
+	// zeroes that would be indistinguishable from a single zero-valued row.
+	nullRow := []sqltypes.Value{sqltypes.NULL, sqltypes.NULL, sqltypes.NULL}
+	sumX, sumX2, n, err = mergeVariancePartial(0, 0, 0, nullRow)
+	if err != nil {
+		t.Fatalf("mergeVariancePartial() failed: %v", err)
+	}
+	if sumX != 0 || sumX2 != 0 || n != 0 {
+		t.Errorf("mergeVariancePartial() with no rows = (%v, %v, %v), want (0, 0, 0)", sumX, sumX2, n)
+	}
+
+	if _, _, _, err := mergeVariancePartial(0, 0, 0, row(1, 1, 1)[:2]); err == nil {
+		t.Error("mergeVariancePartial() with wrong partial count: expected error, got nil")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// CreateView is an engine primitive for CREATE VIEW. It fans the DDL out to
+// every shard of Keyspace so that replicas stay consistent with the view
+// definition; there is no single authoritative shard for a view the way
+// there is for a row.
+type CreateView struct {
+	Keyspace *vindexes.Keyspace
+	Query    string
+	ViewName string
+}
+
+// RouteType returns a description of the plan.
+func (c *CreateView) RouteType() string {
+	return "CreateView"
+}
+
+// GetKeyspaceName specifies the keyspace that this primitive routes to.
+func (c *CreateView) GetKeyspaceName() string {
+	return c.Keyspace.Name
+}
+
+// GetTableName specifies the table (view) that this primitive routes to.
+func (c *CreateView) GetTableName() string {
+	return c.ViewName
+}
+
+// Execute fans the CREATE VIEW DDL out to every shard in the keyspace.
+func (c *CreateView) Execute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	rss, _, err := vcursor.ResolveDestinations(c.Keyspace.Name, nil, []key.Destination{key.DestinationAllShards{}})
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = &querypb.BoundQuery{Sql: c.Query, BindVariables: bindVars}
+	}
+	result, errs := vcursor.ExecuteMultiShard(rss, queries, false /* rollbackOnError */, true /* canAutocommit */)
+	if len(errs) > 0 {
+		return nil, vterrors.Aggregate(errs)
+	}
+	return result, nil
+}
+
+// StreamExecute doesn't stream for CREATE VIEW: a DDL has no rows to
+// stream back, so it's executed the same way as Execute.
+func (c *CreateView) StreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	result, err := c.Execute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(result)
+}
+
+// GetFields is not reachable for CREATE VIEW, which never appears as the
+// right-hand side of a join.
+func (c *CreateView) GetFields(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, fmt.Errorf("BUG: unreachable code for %q", c.Query)
+}
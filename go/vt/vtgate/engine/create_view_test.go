@@ -0,0 +1,85 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// fakeCreateViewCursor is a minimal VCursor recording what CreateView.
+// Execute asks it to resolve and run, standing in for the real gateway that
+// would resolve a keyspace's shards and send a query to each.
+type fakeCreateViewCursor struct {
+	shardCount  int
+	gotKeyspace string
+	gotQueries  []*querypb.BoundQuery
+}
+
+func (f *fakeCreateViewCursor) ResolveDestinations(keyspace string, ids []*querypb.Value, destinations []key.Destination) ([]*srvtopo.ResolvedShard, [][]*querypb.Value, error) {
+	f.gotKeyspace = keyspace
+	return make([]*srvtopo.ResolvedShard, f.shardCount), nil, nil
+}
+
+func (f *fakeCreateViewCursor) ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error) {
+	f.gotQueries = queries
+	return &sqltypes.Result{}, nil
+}
+
+func TestCreateViewExecuteFansOutToAllShards(t *testing.T) {
+	cv := &CreateView{
+		Keyspace: &vindexes.Keyspace{Name: "ks", Sharded: true},
+		Query:    "create algorithm = undefined sql security definer view v1 as select id from t",
+		ViewName: "v1",
+	}
+	vc := &fakeCreateViewCursor{shardCount: 3}
+
+	if _, err := cv.Execute(vc, nil, false); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	if vc.gotKeyspace != "ks" {
+		t.Errorf("ResolveDestinations keyspace = %q, want %q", vc.gotKeyspace, "ks")
+	}
+	if len(vc.gotQueries) != 3 {
+		t.Fatalf("len(queries) = %d, want 3 (one per shard)", len(vc.gotQueries))
+	}
+	for i, q := range vc.gotQueries {
+		if q.Sql != cv.Query {
+			t.Errorf("queries[%d].Sql = %q, want %q", i, q.Sql, cv.Query)
+		}
+	}
+}
+
+func TestCreateViewRouteType(t *testing.T) {
+	cv := &CreateView{Keyspace: &vindexes.Keyspace{Name: "ks"}, ViewName: "v1"}
+	if got := cv.RouteType(); got != "CreateView" {
+		t.Errorf("RouteType() = %q, want %q", got, "CreateView")
+	}
+	if got := cv.GetKeyspaceName(); got != "ks" {
+		t.Errorf("GetKeyspaceName() = %q, want %q", got, "ks")
+	}
+	if got := cv.GetTableName(); got != "v1" {
+		t.Errorf("GetTableName() = %q, want %q", got, "v1")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+// varianceOpcodeByFuncName maps the SQL function name of a variance/stddev
+// aggregate to the engine opcode that reconstructs it from partial sums on
+// vtgate. VARIANCE and STDDEV are MySQL's aliases for VAR_POP and
+// STDDEV_POP respectively.
+var varianceOpcodeByFuncName = map[string]engine.AggregateOpcode{
+	"var_pop":     engine.AggregateVarPop,
+	"variance":    engine.AggregateVarPop,
+	"var_samp":    engine.AggregateVarSamp,
+	"stddev_pop":  engine.AggregateStddevPop,
+	"stddev":      engine.AggregateStddevPop,
+	"stddev_samp": engine.AggregateStddevSamp,
+}
+
+// isVarianceFunc reports whether fn is one of the population/sample
+// variance or standard deviation aggregates, returning the opcode
+// OrderedAggregate should use to recombine its partials.
+func isVarianceFunc(fn *sqlparser.FuncExpr) (engine.AggregateOpcode, bool) {
+	opcode, ok := varianceOpcodeByFuncName[fn.Name.Lowered()]
+	return opcode, ok
+}
+
+// rewriteVarianceAggregate rewrites a single VAR_POP/VAR_SAMP/STDDEV_POP/
+// STDDEV_SAMP call into the three per-shard projections -- sum(x), sum(x*x)
+// and count(x) -- that must be scattered in its place so the partials can be
+// merged once they reach vtgate. It composes with GROUP BY unchanged: the
+// three partials are emitted per group, same as any other aggregate.
+func rewriteVarianceAggregate(fn *sqlparser.FuncExpr) (sqlparser.SelectExprs, error) {
+	aliased, ok := fn.Exprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil, fmt.Errorf("unsupported: %s(*)", fn.Name.String())
+	}
+	x := aliased.Expr
+	square := &sqlparser.BinaryExpr{Operator: sqlparser.MultStr, Left: x, Right: x}
+
+	return sqlparser.SelectExprs{
+		aggregateCall("sum", x),
+		aggregateCall("sum", square),
+		aggregateCall("count", x),
+	}, nil
+}
+
+func aggregateCall(funcName string, arg sqlparser.Expr) *sqlparser.AliasedExpr {
+	return &sqlparser.AliasedExpr{
+		Expr: &sqlparser.FuncExpr{
+			Name:  sqlparser.NewColIdent(funcName),
+			Exprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: arg}},
+		},
+	}
+}
+
+// plainAggregateOpcodeByFuncName maps the SQL function name of an aggregate
+// that OrderedAggregate can merge directly, column for column, without any
+// rewrite of the projection.
+var plainAggregateOpcodeByFuncName = map[string]engine.AggregateOpcode{
+	"count": engine.AggregateCount,
+	"sum":   engine.AggregateSum,
+	"min":   engine.AggregateMin,
+	"max":   engine.AggregateMax,
+}
+
+// rewriteAggregates walks a SELECT's column list, expanding any VAR_POP/
+// VAR_SAMP/STDDEV_POP/STDDEV_SAMP call into its three per-shard partials and
+// leaving every other expression untouched, including plain aggregates like
+// COUNT/SUM/MIN/MAX. It returns:
+//   - the column list to scatter to each shard, with variance aggregates
+//     expanded to three columns (sum(x), sum(x*x), count(x));
+//   - the AggregateParams OrderedAggregate needs to merge the results back,
+//     Col pointing at the (first, for variance) scattered column;
+//   - the scattered column index of every non-aggregate column, in the
+//     order it's referenced by resultColumns;
+//   - resultColumns, which maps the final, logical result -- one column per
+//     original select expression -- back onto aggregates/passthroughCols, so
+//     OrderedAggregate can project the merged row down to what the caller
+//     actually selected.
+//
+// The rewrite has no special interaction with GROUP BY: the group-by
+// expressions are carried through to the scattered query unchanged, and
+// OrderedAggregate merges the partials per group the same way it merges
+// count/sum/min/max.
+func rewriteAggregates(exprs sqlparser.SelectExprs) (out sqlparser.SelectExprs, aggregates []engine.AggregateParams, passthroughCols []int, resultColumns []engine.ResultColumn, err error) {
+	passthrough := func(expr sqlparser.SelectExpr) {
+		resultColumns = append(resultColumns, engine.ResultColumn{Index: len(passthroughCols)})
+		passthroughCols = append(passthroughCols, len(out))
+		out = append(out, expr)
+	}
+
+	for _, expr := range exprs {
+		aliased, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			passthrough(expr)
+			continue
+		}
+		fn, ok := aliased.Expr.(*sqlparser.FuncExpr)
+		if !ok {
+			passthrough(expr)
+			continue
+		}
+		if opcode, ok := isVarianceFunc(fn); ok {
+			partials, rerr := rewriteVarianceAggregate(fn)
+			if rerr != nil {
+				return nil, nil, nil, nil, rerr
+			}
+			resultColumns = append(resultColumns, engine.ResultColumn{IsAggregate: true, Index: len(aggregates)})
+			aggregates = append(aggregates, engine.AggregateParams{Opcode: opcode, Col: len(out), Alias: sqlparser.String(fn)})
+			out = append(out, partials...)
+			continue
+		}
+		if opcode, ok := plainAggregateOpcodeByFuncName[fn.Name.Lowered()]; ok {
+			resultColumns = append(resultColumns, engine.ResultColumn{IsAggregate: true, Index: len(aggregates)})
+			aggregates = append(aggregates, engine.AggregateParams{Opcode: opcode, Col: len(out)})
+			out = append(out, expr)
+			continue
+		}
+		passthrough(expr)
+	}
+	return out, aggregates, passthroughCols, resultColumns, nil
+}
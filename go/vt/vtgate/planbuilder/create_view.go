@@ -0,0 +1,71 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"errors"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// buildCreateViewPlan builds the instructions for a CREATE VIEW statement.
+// The view body is resolved the same way a SELECT would be so that we can
+// reject views whose body spans more than one keyspace; the resulting DDL is
+// then fanned out to every shard of that keyspace, and the view's SELECT is
+// recorded in the vschema so that later SELECTs against the view can be
+// planned by inlining it.
+func buildCreateViewPlan(ddl *sqlparser.DDL, vschema VSchema) (*engine.CreateView, error) {
+	spec := ddl.View
+	if spec == nil {
+		return nil, errors.New("internal error: CREATE VIEW statement is missing its view spec")
+	}
+
+	sel, ok := spec.Select.(*sqlparser.Select)
+	if !ok {
+		return nil, errors.New("unsupported: CREATE VIEW ... UNION")
+	}
+
+	bldr, err := processTableExprs(sel.From, vschema)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := bldr.(*route)
+	if !ok {
+		return nil, errors.New("unsupported: CREATE VIEW with a select that spans more than one keyspace")
+	}
+	if rb.ERoute.TargetDestination != nil {
+		return nil, errors.New("unsupported: CREATE VIEW with a target destination")
+	}
+
+	keyspace := rb.ERoute.Keyspace
+	viewName := spec.ViewName.Name.String()
+	ecv := &engine.CreateView{
+		Keyspace: keyspace,
+		Query:    generateQuery(ddl),
+		ViewName: viewName,
+	}
+
+	if err := vschema.AddView(keyspace.Name, viewName, sel); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", err)
+	}
+
+	return ecv, nil
+}
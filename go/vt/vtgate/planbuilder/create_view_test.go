@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// fakeVSchema is a VSchema that errors on every lookup. It's only good for
+// exercising the buildCreateViewPlan paths that reject a statement before
+// ever consulting the schema; the keyspace-resolution/TargetDestination
+// paths route through processTableExprs and *route, which aren't part of
+// this source tree and so aren't reachable from a fake VSchema alone.
+type fakeVSchema struct{}
+
+func (fakeVSchema) FindTable(tablename sqlparser.TableName) (*vindexes.Table, string, topodatapb.TabletType, key.Destination, error) {
+	return nil, "", topodatapb.TabletType_MASTER, nil, errors.New("fakeVSchema: not implemented")
+}
+
+func (fakeVSchema) FindTablesOrVindex(tablename sqlparser.TableName) ([]*vindexes.Table, vindexes.Vindex, string, topodatapb.TabletType, key.Destination, error) {
+	return nil, nil, "", topodatapb.TabletType_MASTER, nil, errors.New("fakeVSchema: not implemented")
+}
+
+func (fakeVSchema) DefaultKeyspace() (*vindexes.Keyspace, error) {
+	return nil, errors.New("fakeVSchema: no default keyspace")
+}
+
+func (fakeVSchema) TargetString() string { return "" }
+
+func (fakeVSchema) AddView(keyspace, viewName string, sel sqlparser.SelectStatement) error {
+	return nil
+}
+
+func (fakeVSchema) FindView(keyspace, viewName string) *vindexes.View { return nil }
+
+func TestBuildCreateViewPlanRejectsMissingViewSpec(t *testing.T) {
+	ddl := &sqlparser.DDL{Action: sqlparser.CreateViewStr}
+
+	_, err := buildCreateViewPlan(ddl, fakeVSchema{})
+	if err == nil {
+		t.Fatal("buildCreateViewPlan() with no View spec: expected error, got nil")
+	}
+}
+
+func TestBuildCreateViewPlanRejectsUnion(t *testing.T) {
+	ddl := &sqlparser.DDL{
+		Action: sqlparser.CreateViewStr,
+		View: &sqlparser.ViewSpec{
+			ViewName: sqlparser.TableName{Name: sqlparser.NewTableIdent("v1")},
+			Select:   &sqlparser.Union{},
+		},
+	}
+
+	_, err := buildCreateViewPlan(ddl, fakeVSchema{})
+	if err == nil {
+		t.Fatal("buildCreateViewPlan() with a UNION body: expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "UNION") {
+		t.Errorf("buildCreateViewPlan() error = %q, want it to mention UNION", err.Error())
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// buildDDLPlan builds the instructions for a DDL statement, the way
+// buildUpdatePlan does for an UPDATE. It dispatches on the DDL's action,
+// routing CREATE VIEW to buildCreateViewPlan; other actions aren't planned
+// by this package yet.
+func buildDDLPlan(ddl *sqlparser.DDL, vschema VSchema) (*engine.CreateView, error) {
+	switch ddl.Action {
+	case sqlparser.CreateViewStr:
+		return buildCreateViewPlan(ddl, vschema)
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: DDL action %v", ddl.Action)
+	}
+}
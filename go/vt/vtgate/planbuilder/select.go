@@ -0,0 +1,79 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"errors"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+// buildSelectPlan builds the instructions for a SELECT statement, alongside
+// buildUpdatePlan's DML path. The only pushdown handled here today is
+// aggregation: count/sum/min/max and the var_pop/var_samp/stddev_pop/
+// stddev_samp family are rewritten by rewriteAggregates and merged back by
+// an OrderedAggregate primitive; a SELECT with none of those is just routed
+// as given.
+func buildSelectPlan(sel *sqlparser.Select, vschema VSchema) (engine.Primitive, error) {
+	bldr, err := processTableExprs(sel.From, vschema)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := bldr.(*route)
+	if !ok {
+		return nil, errors.New("unsupported: select that spans more than one keyspace")
+	}
+
+	rewritten, aggregates, passthroughCols, resultColumns, err := rewriteAggregates(sel.SelectExprs)
+	if err != nil {
+		return nil, err
+	}
+	if len(aggregates) == 0 {
+		rb.ERoute.Query = generateQuery(sel)
+		return rb.ERoute, nil
+	}
+
+	scattered := *sel
+	scattered.SelectExprs = rewritten
+	rb.ERoute.Query = generateQuery(&scattered)
+
+	return &engine.OrderedAggregate{
+		Aggregates:      aggregates,
+		Keys:            groupByColumns(sel.GroupBy, rewritten),
+		PassthroughCols: passthroughCols,
+		ResultColumns:   resultColumns,
+		Input:           rb.ERoute,
+	}, nil
+}
+
+// groupByColumns resolves each GROUP BY expression to its column index in
+// the (possibly rewritten) select list that comes back from a shard, so
+// OrderedAggregate knows which columns identify a group.
+func groupByColumns(groupBy sqlparser.GroupBy, exprs sqlparser.SelectExprs) []int {
+	var keys []int
+	for _, gb := range groupBy {
+		for i, expr := range exprs {
+			aliased, ok := expr.(*sqlparser.AliasedExpr)
+			if ok && sqlparser.String(aliased.Expr) == sqlparser.String(gb) {
+				keys = append(keys, i)
+				break
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// VSchema defines the interface that this package uses to fetch routing
+// schema info. It is satisfied by vindexes.VSchema scoped to the session's
+// default keyspace, tablet type and destination.
+type VSchema interface {
+	FindTable(tablename sqlparser.TableName) (*vindexes.Table, string, topodatapb.TabletType, key.Destination, error)
+	FindTablesOrVindex(tablename sqlparser.TableName) ([]*vindexes.Table, vindexes.Vindex, string, topodatapb.TabletType, key.Destination, error)
+	DefaultKeyspace() (*vindexes.Keyspace, error)
+	TargetString() string
+
+	// AddView registers a view's SELECT body against keyspace so that later
+	// SELECT statements naming the view can be planned by inlining it.
+	AddView(keyspace, viewName string, sel sqlparser.SelectStatement) error
+	// FindView returns the stored definition for viewName in keyspace, or
+	// nil if no such view has been created.
+	FindView(keyspace, viewName string) *vindexes.View
+}
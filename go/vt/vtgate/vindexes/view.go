@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// View holds a stored view definition for a single keyspace. Vitess doesn't
+// yet plan SELECTs against a view by routing to it directly; instead, the
+// view's SELECT is inlined wherever the view name is referenced.
+type View struct {
+	Name   string
+	Select sqlparser.SelectStatement
+}
+
+// AddView registers a view's SELECT body against its keyspace so that later
+// SELECT statements naming the view can be planned by inlining it in place
+// of the table reference. It overwrites any previous definition under the
+// same name, matching the "last CREATE VIEW wins" semantics of the DDL
+// itself once it has been applied to every shard.
+func (vschema *VSchema) AddView(ksName, viewName string, sel sqlparser.SelectStatement) error {
+	ks, ok := vschema.Keyspaces[ksName]
+	if !ok {
+		return fmt.Errorf("keyspace %s not found in vschema", ksName)
+	}
+	if ks.Views == nil {
+		ks.Views = make(map[string]*View)
+	}
+	ks.Views[viewName] = &View{
+		Name:   viewName,
+		Select: sel,
+	}
+	return nil
+}
+
+// FindView returns the stored definition for viewName in keyspace ksName, if
+// one has been created.
+func (vschema *VSchema) FindView(ksName, viewName string) *View {
+	ks, ok := vschema.Keyspaces[ksName]
+	if !ok {
+		return nil
+	}
+	return ks.Views[viewName]
+}
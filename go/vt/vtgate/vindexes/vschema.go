@@ -0,0 +1,44 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+// Keyspace contains the name and sharding state of a single keyspace, as
+// referenced by the routes and DML/DDL primitives that target it.
+type Keyspace struct {
+	Name    string
+	Sharded bool
+}
+
+// KeyspaceSchema contains everything the planbuilder needs to know about a
+// single keyspace: its identity, and the views that have been created
+// against it. Tables and vindexes are looked up through VSchema.FindTable/
+// FindTablesOrVindex rather than off this struct directly.
+type KeyspaceSchema struct {
+	Keyspace *Keyspace
+
+	// Views holds every view created against this keyspace, keyed by view
+	// name, so that a later SELECT naming the view can be planned by
+	// inlining its stored SELECT. It's initialized lazily by AddView.
+	Views map[string]*View
+}
+
+// VSchema is the denormalized routing schema for every keyspace vtgate
+// knows about. planbuilder.VSchema is the narrower, per-statement interface
+// this type satisfies.
+type VSchema struct {
+	Keyspaces map[string]*KeyspaceSchema
+}